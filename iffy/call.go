@@ -0,0 +1,134 @@
+package iffy
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// Call represents a single HTTP call performed by a Tester.
+// It is built fluently via Tester.AddCall and its chained setters.
+type Call struct {
+	Name     string
+	Method   string
+	QueryStr string
+	Body     Body
+
+	headers    Headers
+	checkers   []Checker
+	respObject interface{}
+
+	snapshotBody    bool
+	snapshotHeaders []string
+	jsonNormalizers []JSONNormalizer
+
+	dependsOn []string
+
+	retryAttempts int
+	retryBackoff  time.Duration
+	retryUntil    Checker
+
+	repeat int
+}
+
+// DependsOn declares that this call must only run after the named calls
+// have completed. It only has an effect when the Tester runs with a
+// parallelism greater than 1 (see Tester.SetParallelism); calls are
+// otherwise already run in declaration order.
+func (c *Call) DependsOn(names ...string) *Call {
+	c.dependsOn = append(c.dependsOn, names...)
+	return c
+}
+
+// Retry re-issues this call up to attempts times, waiting backoff between
+// each, until until passes against the response (or attempts is exhausted,
+// in which case the last response is used as-is). Useful for endpoints that
+// become consistent asynchronously. until's respObject argument is rebound
+// from each attempt's own body (via BindResponse's target, if any) before
+// it's called, not left over from a previous call or attempt.
+func (c *Call) Retry(attempts int, backoff time.Duration, until Checker) *Call {
+	c.retryAttempts = attempts
+	c.retryBackoff = backoff
+	c.retryUntil = until
+	return c
+}
+
+// Repeat re-issues this call n times in a row and reports aggregated
+// latency stats through t.Logf, turning the call into a light load/soak
+// check in addition to a functional one. Checkers and snapshotting only
+// apply to the last repetition's response.
+func (c *Call) Repeat(n int) *Call {
+	c.repeat = n
+	return c
+}
+
+// Headers sets the headers sent with this call.
+// Both keys and values are run through the Tester's templater.
+func (c *Call) Headers(h Headers) *Call {
+	c.headers = h
+	return c
+}
+
+// Checkers appends checkers that will be run against this call's response.
+func (c *Call) Checkers(checkers ...Checker) *Call {
+	c.checkers = append(c.checkers, checkers...)
+	return c
+}
+
+// BindResponse unmarshals the JSON response body into o once the call completes.
+func (c *Call) BindResponse(o interface{}) *Call {
+	c.respObject = o
+	return c
+}
+
+// Body abstracts the request body of a Call, so that different
+// encodings (plain string, JSON, ...) can be plugged in.
+type Body interface {
+	// GetBody returns the io.Reader to use as the request body.
+	// tmpl should be applied to any templated content before encoding.
+	GetBody(tmpl TemplaterFunc) (io.Reader, error)
+	// ContentType returns the value to set as the request's content-type header.
+	ContentType() string
+}
+
+// NoopBody is a Body with no content, used for calls that don't send a body.
+type NoopBody struct{}
+
+func (n *NoopBody) GetBody(tmpl TemplaterFunc) (io.Reader, error) {
+	return nil, nil
+}
+
+func (n *NoopBody) ContentType() string {
+	return ""
+}
+
+// StringBody is a Body whose content is a raw, templated string.
+type StringBody struct {
+	S string
+}
+
+func (s *StringBody) GetBody(tmpl TemplaterFunc) (io.Reader, error) {
+	return bytes.NewBufferString(tmpl(s.S)), nil
+}
+
+func (s *StringBody) ContentType() string {
+	return "application/json"
+}
+
+// JSONBody is a Body whose content is marshaled from an arbitrary Go value.
+type JSONBody struct {
+	Data interface{}
+}
+
+func (j *JSONBody) GetBody(tmpl TemplaterFunc) (io.Reader, error) {
+	b, err := json.Marshal(j.Data)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewBuffer(b), nil
+}
+
+func (j *JSONBody) ContentType() string {
+	return "application/json"
+}
@@ -8,6 +8,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
 	"text/template"
 )
@@ -17,6 +18,13 @@ type Tester struct {
 	r      http.Handler
 	Calls  []*Call
 	values Values
+
+	// valuesMu guards values, read by applyTemplate and written by finish;
+	// calls may run concurrently once SetParallelism(n > 1) is used.
+	valuesMu sync.Mutex
+
+	snapshotDir string
+	parallelism int
 }
 
 type Headers map[string]string
@@ -60,60 +68,82 @@ func (t *Tester) AddCall(name, method, querystr string, body interface{}) *Call
 	return c
 }
 
-func (t *Tester) Run() {
-	for _, c := range t.Calls {
-		body, err := c.Body.GetBody(t.applyTemplate)
-		if err != nil {
-			t.t.Error(err)
-			continue
+// runOnce performs a single HTTP round-trip for c and returns the raw
+// response, its body, and any transport/templating-level error. It does not
+// run checkers or record snapshots/values; see finish for that.
+func (t *Tester) runOnce(c *Call) (*http.Response, []byte, error) {
+	body, err := c.Body.GetBody(t.applyTemplate)
+	if err != nil {
+		return nil, nil, err
+	}
+	req, err := http.NewRequest(c.Method, t.applyTemplate(c.QueryStr), body)
+	if err != nil {
+		return nil, nil, err
+	}
+	contentType := c.Body.ContentType()
+	if contentType != "" {
+		req.Header.Set("content-type", c.Body.ContentType())
+	}
+	if c.headers != nil {
+		for k, v := range c.headers {
+			req.Header.Set(t.applyTemplate(k), t.applyTemplate(v))
 		}
-		req, err := http.NewRequest(c.Method, t.applyTemplate(c.QueryStr), body)
+	}
+	w := httptest.NewRecorder()
+	t.r.ServeHTTP(w, req)
+	resp := w.Result()
+
+	var rawBody []byte
+	if resp.Body != nil {
+		rawBody, err = ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
 		if err != nil {
-			t.t.Error(err)
-			continue
-		}
-		contentType := c.Body.ContentType()
-		if contentType != "" {
-			req.Header.Set("content-type", c.Body.ContentType())
-		}
-		if c.headers != nil {
-			for k, v := range c.headers {
-				req.Header.Set(t.applyTemplate(k), t.applyTemplate(v))
-			}
-		}
-		w := httptest.NewRecorder()
-		t.r.ServeHTTP(w, req)
-		resp := w.Result()
-		var respBody string
-		if resp.Body != nil {
-			rb, err := ioutil.ReadAll(resp.Body)
-			if err != nil {
-				t.t.Error(err)
-			}
-			respBody = string(rb)
-			resp.Body.Close()
-			if c.respObject != nil {
-				err = json.Unmarshal(rb, c.respObject)
-				if err != nil {
-					t.t.Error(err)
-					continue
-				}
-			}
-			var retJson map[string]interface{}
-			_ = json.Unmarshal(rb, &retJson)
-			t.values[c.Name] = retJson
+			return resp, nil, err
 		}
+	}
+	return resp, rawBody, nil
+}
+
+// finish binds c.respObject, records c's response for later templating,
+// runs its snapshot check, and runs its checkers against resp/rawBody.
+func (t *Tester) finish(c *Call, resp *http.Response, rawBody []byte) {
+	if resp.Body == nil && rawBody == nil {
 		for _, checker := range c.checkers {
-			err := checker(resp, respBody, c.respObject)
-			if err != nil {
+			if err := checker(resp, "", c.respObject); err != nil {
 				t.t.Errorf("%s: %s", c.Name, err)
 			}
 		}
+		return
+	}
+
+	if c.respObject != nil {
+		if err := json.Unmarshal(rawBody, c.respObject); err != nil {
+			t.t.Error(err)
+			return
+		}
+	}
+
+	var retJson map[string]interface{}
+	_ = json.Unmarshal(rawBody, &retJson)
+
+	t.valuesMu.Lock()
+	t.values[c.Name] = retJson
+	t.valuesMu.Unlock()
+
+	t.runSnapshot(c, resp, rawBody)
+
+	respBody := string(rawBody)
+	for _, checker := range c.checkers {
+		if err := checker(resp, respBody, c.respObject); err != nil {
+			t.t.Errorf("%s: %s", c.Name, err)
+		}
 	}
 }
 
 func (t *Tester) applyTemplate(s string) string {
+	t.valuesMu.Lock()
 	b, err := t.values.Apply(s)
+	t.valuesMu.Unlock()
 	if err != nil {
 		t.t.Error(err)
 		return ""
@@ -126,8 +156,9 @@ type Values map[string]interface{}
 func (v Values) Apply(templateStr string) ([]byte, error) {
 
 	var funcMap = template.FuncMap{
-		"field": v.fieldTmpl,
-		"json":  v.jsonFieldTmpl,
+		"field":    v.fieldTmpl,
+		"json":     v.jsonFieldTmpl,
+		"jsonpath": v.jsonPathTmpl,
 	}
 
 	tmpl, err := template.New("tmpl").Funcs(funcMap).Parse(templateStr)
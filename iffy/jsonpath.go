@@ -0,0 +1,203 @@
+package iffy
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Matcher asserts something about a single value extracted by
+// ExpectJSONPath or the {{ jsonpath ... }} template function.
+type Matcher func(v interface{}) error
+
+// Equals matches if the extracted value equals want, compared after a JSON
+// round-trip so e.g. float64(1) matches int(1).
+func Equals(want interface{}) Matcher {
+	return func(v interface{}) error {
+		got, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		wantJSON, err := json.Marshal(want)
+		if err != nil {
+			return err
+		}
+		if string(got) != string(wantJSON) {
+			return fmt.Errorf("expected %s, got %s", wantJSON, got)
+		}
+		return nil
+	}
+}
+
+// Regex matches if the extracted value, stringified, matches re.
+func Regex(re string) Matcher {
+	r := regexp.MustCompile(re)
+	return func(v interface{}) error {
+		s := fmt.Sprintf("%v", v)
+		if !r.MatchString(s) {
+			return fmt.Errorf("value %q doesn't match regex %q", s, re)
+		}
+		return nil
+	}
+}
+
+// LengthEquals matches if the extracted value is a string (length in bytes),
+// a JSON array or a JSON object (number of members) of length n.
+func LengthEquals(n int) Matcher {
+	return func(v interface{}) error {
+		l, err := jsonLength(v)
+		if err != nil {
+			return err
+		}
+		if l != n {
+			return fmt.Errorf("expected length %d, got %d", n, l)
+		}
+		return nil
+	}
+}
+
+// TypeIs matches if the extracted value's JSON type ("string", "number",
+// "boolean", "array", "object", or "null") is typ.
+func TypeIs(typ string) Matcher {
+	return func(v interface{}) error {
+		got := jsonTypeOf(v)
+		if got != typ {
+			return fmt.Errorf("expected type %q, got %q", typ, got)
+		}
+		return nil
+	}
+}
+
+// Contains matches if the extracted value is a JSON array containing want,
+// or a string containing want as a substring.
+func Contains(want interface{}) Matcher {
+	return func(v interface{}) error {
+		switch vv := v.(type) {
+		case string:
+			s, ok := want.(string)
+			if !ok || !strings.Contains(vv, s) {
+				return fmt.Errorf("expected %q to contain %v", vv, want)
+			}
+			return nil
+		case []interface{}:
+			for _, item := range vv {
+				if Equals(want)(item) == nil {
+					return nil
+				}
+			}
+			return fmt.Errorf("expected %v to contain %v", vv, want)
+		default:
+			return fmt.Errorf("cannot check Contains against %T", v)
+		}
+	}
+}
+
+func jsonLength(v interface{}) (int, error) {
+	switch vv := v.(type) {
+	case string:
+		return len(vv), nil
+	case []interface{}:
+		return len(vv), nil
+	case map[string]interface{}:
+		return len(vv), nil
+	default:
+		return 0, fmt.Errorf("cannot measure length of %T", v)
+	}
+}
+
+func jsonTypeOf(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case float64, int:
+		return "number"
+	case bool:
+		return "boolean"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+// ExpectJSONPath decodes the response body as JSON and runs matcher against
+// the value found at path (a JSONPath expression, e.g.
+// "$.items[0].id"), unlike ExpectJSONBranch it can traverse arrays and
+// apply arbitrary matchers instead of only checking a leaf's string value.
+func ExpectJSONPath(path string, matcher Matcher) Checker {
+	return func(r *http.Response, body string, respObject interface{}) error {
+		var data interface{}
+		if err := json.Unmarshal([]byte(body), &data); err != nil {
+			return err
+		}
+		v, err := jsonPath(data, path)
+		if err != nil {
+			return err
+		}
+		return matcher(v)
+	}
+}
+
+// jsonPath resolves a dotted/bracket JSONPath expression (e.g.
+// "$.calls.foo.items[0].id") against decoded JSON data. A leading "$." or
+// "$" is optional.
+func jsonPath(data interface{}, path string) (interface{}, error) {
+	path = strings.TrimPrefix(path, "$")
+	path = strings.TrimPrefix(path, ".")
+	if path == "" {
+		return data, nil
+	}
+
+	cur := data
+	for _, tok := range splitJSONPath(path) {
+		switch node := cur.(type) {
+		case map[string]interface{}:
+			v, ok := node[tok]
+			if !ok {
+				return nil, fmt.Errorf("jsonpath: no such field %q", tok)
+			}
+			cur = v
+		case []interface{}:
+			idx, err := strconv.Atoi(tok)
+			if err != nil {
+				return nil, fmt.Errorf("jsonpath: %q is not a valid array index", tok)
+			}
+			if idx < 0 || idx >= len(node) {
+				return nil, fmt.Errorf("jsonpath: index %d out of range", idx)
+			}
+			cur = node[idx]
+		default:
+			return nil, fmt.Errorf("jsonpath: cannot descend into %T at %q", cur, tok)
+		}
+	}
+	return cur, nil
+}
+
+// splitJSONPath turns "items[0].id" into ["items", "0", "id"].
+func splitJSONPath(path string) []string {
+	path = strings.ReplaceAll(path, "[", ".")
+	path = strings.ReplaceAll(path, "]", "")
+	var tokens []string
+	for _, t := range strings.Split(path, ".") {
+		if t != "" {
+			tokens = append(tokens, t)
+		}
+	}
+	return tokens
+}
+
+// jsonPathTmpl backs the {{ jsonpath "..." }} template function: values
+// recorded by previous calls are exposed under the "calls" root, so
+// "$.calls.foo.items[0].id" reaches the "id" field of the first item in
+// call "foo"'s response.
+func (v Values) jsonPathTmpl(path string) (interface{}, error) {
+	root := map[string]interface{}{"calls": map[string]interface{}(v)}
+	return jsonPath(root, path)
+}
@@ -0,0 +1,98 @@
+package iffy
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func decodeForTest(t *testing.T, raw string) interface{} {
+	t.Helper()
+	var data interface{}
+	if err := json.Unmarshal([]byte(raw), &data); err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestJSONPathTraversal(t *testing.T) {
+	data := decodeForTest(t, `{"items":[{"id":1,"tags":["a","b"]},{"id":2,"tags":[]}],"count":2}`)
+
+	cases := []struct {
+		path string
+		want interface{}
+	}{
+		{"$.count", float64(2)},
+		{"$.items[0].id", float64(1)},
+		{"$.items[1].id", float64(2)},
+		{"items[0].tags[1]", "b"},
+	}
+	for _, tc := range cases {
+		got, err := jsonPath(data, tc.path)
+		if err != nil {
+			t.Errorf("jsonPath(%q): %s", tc.path, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("jsonPath(%q) = %#v, want %#v", tc.path, got, tc.want)
+		}
+	}
+}
+
+func TestJSONPathErrors(t *testing.T) {
+	data := decodeForTest(t, `{"items":[1,2,3]}`)
+
+	cases := []string{
+		"$.missing",
+		"$.items[10]",
+		"$.items[notanumber]",
+		"$.items.foo",
+	}
+	for _, p := range cases {
+		if _, err := jsonPath(data, p); err == nil {
+			t.Errorf("expected jsonPath(%q) to error", p)
+		}
+	}
+}
+
+func TestExpectJSONPathChecker(t *testing.T) {
+	body := `{"items":[{"id":1},{"id":2}]}`
+
+	if err := ExpectJSONPath("$.items[1].id", Equals(2))(&http.Response{}, body, nil); err != nil {
+		t.Fatalf("expected checker to pass, got: %s", err)
+	}
+	if err := ExpectJSONPath("$.items[1].id", Equals(99))(&http.Response{}, body, nil); err == nil {
+		t.Fatal("expected checker to fail for a mismatched value")
+	}
+}
+
+func TestMatchers(t *testing.T) {
+	if err := LengthEquals(3)("abc"); err != nil {
+		t.Errorf("LengthEquals: %s", err)
+	}
+	if err := TypeIs("array")([]interface{}{1, 2}); err != nil {
+		t.Errorf("TypeIs: %s", err)
+	}
+	if err := Contains("ell")("hello"); err != nil {
+		t.Errorf("Contains(string): %s", err)
+	}
+	if err := Contains(float64(2))([]interface{}{float64(1), float64(2)}); err != nil {
+		t.Errorf("Contains(array): %s", err)
+	}
+	if err := Regex(`^\d+$`)(42); err != nil {
+		t.Errorf("Regex: %s", err)
+	}
+}
+
+func TestValuesJSONPathTmpl(t *testing.T) {
+	v := Values{"foo": map[string]interface{}{
+		"items": []interface{}{map[string]interface{}{"id": float64(7)}},
+	}}
+	got, err := v.jsonPathTmpl("$.calls.foo.items[0].id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != float64(7) {
+		t.Fatalf("expected 7, got %v", got)
+	}
+}
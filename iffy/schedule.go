@@ -0,0 +1,213 @@
+package iffy
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SetParallelism lets up to n calls run concurrently, provided they declare
+// their ordering constraints via Call.DependsOn. With the default of 1 (or
+// if never called), calls run strictly sequentially in declaration order,
+// exactly as before.
+func (t *Tester) SetParallelism(n int) *Tester {
+	t.parallelism = n
+	return t
+}
+
+// Run executes every call registered on the Tester, in declaration order
+// unless SetParallelism(n > 1) was used, in which case calls with no
+// DependsOn relationship between them may run concurrently.
+func (t *Tester) Run() {
+	if t.parallelism <= 1 {
+		t.runSequential()
+		return
+	}
+	t.runConcurrent()
+}
+
+func (t *Tester) runSequential() {
+	for _, c := range t.Calls {
+		t.execute(c)
+	}
+}
+
+func (t *Tester) runConcurrent() {
+	byName := map[string]*Call{}
+	for _, c := range t.Calls {
+		if c.Name != "" {
+			byName[c.Name] = c
+		}
+	}
+
+	t.validateDependencyGraph(byName)
+
+	done := make(map[*Call]chan struct{}, len(t.Calls))
+	for _, c := range t.Calls {
+		done[c] = make(chan struct{})
+	}
+
+	sem := make(chan struct{}, t.parallelism)
+	var wg sync.WaitGroup
+
+	for _, c := range t.Calls {
+		c := c
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, dep := range c.dependsOn {
+				if d, ok := byName[dep]; ok {
+					<-done[d]
+				}
+			}
+
+			sem <- struct{}{}
+			t.execute(c)
+			<-sem
+
+			close(done[c])
+		}()
+	}
+	wg.Wait()
+}
+
+// validateDependencyGraph reports (via t.t.Fatalf) any DependsOn name that
+// doesn't match a declared call, and any dependency cycle, so runConcurrent
+// can never deadlock waiting forever on a done channel that would never
+// close.
+func (t *Tester) validateDependencyGraph(byName map[string]*Call) {
+	for _, c := range t.Calls {
+		for _, dep := range c.dependsOn {
+			if _, ok := byName[dep]; !ok {
+				t.t.Fatalf("iffy: %q depends on %q, which is not a declared call name", c.Name, dep)
+				return
+			}
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[*Call]int, len(t.Calls))
+
+	var walk func(c *Call, path []string) []string
+	walk = func(c *Call, path []string) []string {
+		state[c] = visiting
+		path = append(path, c.Name)
+		for _, dep := range c.dependsOn {
+			d := byName[dep]
+			switch state[d] {
+			case visiting:
+				return append(path, d.Name)
+			case unvisited:
+				if cycle := walk(d, path); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		state[c] = visited
+		return nil
+	}
+
+	for _, c := range t.Calls {
+		if state[c] == unvisited {
+			if cycle := walk(c, nil); cycle != nil {
+				t.t.Fatalf("iffy: dependency cycle detected: %s", strings.Join(cycle, " -> "))
+				return
+			}
+		}
+	}
+}
+
+// execute runs c, honoring its Retry and Repeat settings, then finishes it
+// (checkers, snapshot, recorded values) against the last response obtained.
+func (t *Tester) execute(c *Call) {
+	repeat := c.repeat
+	if repeat < 1 {
+		repeat = 1
+	}
+
+	var durations []time.Duration
+	var resp *http.Response
+	var rawBody []byte
+
+	for rep := 0; rep < repeat; rep++ {
+		start := time.Now()
+		r, rb, ok := t.attempt(c)
+		durations = append(durations, time.Since(start))
+		if !ok {
+			return
+		}
+		resp, rawBody = r, rb
+	}
+
+	if c.repeat > 1 {
+		t.logRepeatStats(c, durations)
+	}
+
+	t.finish(c, resp, rawBody)
+}
+
+// attempt runs c once, retrying up to c.retryAttempts times (waiting
+// c.retryBackoff between each) until c.retryUntil passes against the
+// response, or until attempts are exhausted, in which case the last
+// response is returned as-is. ok is false only on a transport-level error,
+// which is already reported to t.t.
+func (t *Tester) attempt(c *Call) (resp *http.Response, rawBody []byte, ok bool) {
+	attempts := c.retryAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	for i := 1; i <= attempts; i++ {
+		r, rb, err := t.runOnce(c)
+		if err != nil {
+			t.t.Error(err)
+			return nil, nil, false
+		}
+		resp, rawBody = r, rb
+
+		if c.retryUntil == nil {
+			break
+		}
+		// c.respObject is normally only bound once, by finish(), after the
+		// call is done retrying. retryUntil needs it bound to *this*
+		// attempt's body, not whatever a previous call or attempt left
+		// there, so bind it here too; finish()'s later rebind from the
+		// final rawBody is a harmless no-op repeat of this one.
+		if c.respObject != nil {
+			if err := json.Unmarshal(rb, c.respObject); err != nil {
+				t.t.Error(err)
+				return nil, nil, false
+			}
+		}
+		if c.retryUntil(r, string(rb), c.respObject) == nil {
+			break
+		}
+		if i < attempts {
+			time.Sleep(c.retryBackoff)
+		}
+	}
+
+	return resp, rawBody, true
+}
+
+// logRepeatStats reports aggregated latency stats for a Call.Repeat'd call.
+func (t *Tester) logRepeatStats(c *Call, durations []time.Duration) {
+	var total, min, max time.Duration
+	for i, d := range durations {
+		total += d
+		if i == 0 || d < min {
+			min = d
+		}
+		if d > max {
+			max = d
+		}
+	}
+	avg := total / time.Duration(len(durations))
+	t.t.Logf("%s: %d repeats, min=%s avg=%s max=%s", c.Name, len(durations), min, avg, max)
+}
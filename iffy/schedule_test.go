@@ -0,0 +1,171 @@
+package iffy
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestRunConcurrentFanOutFanIn builds a diamond DAG (a -> {b, c} -> d) and
+// checks that b and c, which only depend on a, actually overlap in time
+// under SetParallelism(2), and that d only runs once both have finished.
+func TestRunConcurrentFanOutFanIn(t *testing.T) {
+	var mu sync.Mutex
+	var current, maxConcurrent int
+	finishedAt := map[string]time.Time{}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		current++
+		if current > maxConcurrent {
+			maxConcurrent = current
+		}
+		mu.Unlock()
+
+		if r.URL.Path == "/b" || r.URL.Path == "/c" {
+			time.Sleep(50 * time.Millisecond)
+		}
+
+		mu.Lock()
+		current--
+		finishedAt[r.URL.Path] = time.Now()
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{}`))
+	})
+
+	tester := NewTester(t, handler)
+	tester.SetParallelism(2)
+
+	tester.AddCall("a", "GET", "/a", nil)
+	tester.AddCall("b", "GET", "/b", nil).DependsOn("a")
+	tester.AddCall("c", "GET", "/c", nil).DependsOn("a")
+	tester.AddCall("d", "GET", "/d", nil).DependsOn("b", "c")
+
+	tester.Run()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if maxConcurrent < 2 {
+		t.Fatalf("expected b and c to run concurrently, observed max in-flight requests: %d", maxConcurrent)
+	}
+	if !finishedAt["/b"].Before(finishedAt["/d"]) {
+		t.Fatalf("expected d to run after b")
+	}
+	if !finishedAt["/c"].Before(finishedAt["/d"]) {
+		t.Fatalf("expected d to run after c")
+	}
+}
+
+// TestValidateDependencyGraphCycle checks that a cyclic DependsOn graph
+// fails the test via t.t.Fatalf instead of deadlocking runConcurrent
+// forever. A regression here would hang rather than fail; go test's own
+// -timeout is what would turn that into a reported failure.
+//
+// t.t.Fatalf has to be exercised in a subprocess: calling it directly would
+// correctly fail, but a subtest failing always marks this test (and the
+// whole package) FAILED too, which is indistinguishable from a real
+// regression in go test's output. Re-exec this same test binary for just
+// the "crasher" case and assert on its exit status instead, the same
+// pattern the standard library itself uses for testing fatal paths.
+func TestValidateDependencyGraphCycle(t *testing.T) {
+	if os.Getenv("IFFY_IS_CYCLIC_CRASHER") == "1" {
+		tester := NewTester(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		tester.SetParallelism(2)
+		tester.AddCall("a", "GET", "/a", nil).DependsOn("b")
+		tester.AddCall("b", "GET", "/b", nil).DependsOn("a")
+
+		tester.Run()
+		return
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestValidateDependencyGraphCycle$", "-test.v")
+	cmd.Env = append(os.Environ(), "IFFY_IS_CYCLIC_CRASHER=1")
+	out, err := cmd.CombinedOutput()
+	if err == nil {
+		t.Fatalf("expected a cyclic DependsOn graph to fail via t.Fatalf, but the subprocess exited cleanly:\n%s", out)
+	}
+	if !bytes.Contains(out, []byte("dependency cycle detected")) {
+		t.Fatalf("expected the cycle-detection message in subprocess output, got:\n%s", out)
+	}
+}
+
+// TestValidateDependencyGraphUnknownName checks that a DependsOn name with
+// no matching call (a typo) is rejected up front rather than blocking
+// forever on a done channel nobody closes. See TestValidateDependencyGraphCycle
+// for why this runs as a subprocess.
+func TestValidateDependencyGraphUnknownName(t *testing.T) {
+	if os.Getenv("IFFY_IS_UNKNOWN_DEP_CRASHER") == "1" {
+		tester := NewTester(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		tester.SetParallelism(2)
+		tester.AddCall("a", "GET", "/a", nil).DependsOn("does-not-exist")
+
+		tester.Run()
+		return
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestValidateDependencyGraphUnknownName$", "-test.v")
+	cmd.Env = append(os.Environ(), "IFFY_IS_UNKNOWN_DEP_CRASHER=1")
+	out, err := cmd.CombinedOutput()
+	if err == nil {
+		t.Fatalf("expected an unknown DependsOn name to fail via t.Fatalf, but the subprocess exited cleanly:\n%s", out)
+	}
+	if !bytes.Contains(out, []byte("not a declared call name")) {
+		t.Fatalf("expected the unknown-dependency message in subprocess output, got:\n%s", out)
+	}
+}
+
+// TestAttemptBindsRespObjectPerAttempt checks that Call.Retry's until
+// checker sees respObject bound to the *current* attempt's body, not
+// whatever a previous attempt (or call) left there.
+func TestAttemptBindsRespObjectPerAttempt(t *testing.T) {
+	var n int32
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempt := atomic.AddInt32(&n, 1)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"attempt":%d}`, attempt)
+	})
+
+	type respBody struct {
+		Attempt int `json:"attempt"`
+	}
+	var out respBody
+	var seenByCheck []int
+
+	tester := NewTester(t, handler)
+	c := tester.AddCall("retry", "GET", "/retry", nil)
+	c.BindResponse(&out)
+	c.Retry(3, time.Millisecond, func(r *http.Response, body string, respObject interface{}) error {
+		rb := respObject.(*respBody)
+		seenByCheck = append(seenByCheck, rb.Attempt)
+		if rb.Attempt < 3 {
+			return fmt.Errorf("not yet at attempt 3")
+		}
+		return nil
+	})
+
+	tester.Run()
+
+	want := []int{1, 2, 3}
+	if len(seenByCheck) != len(want) {
+		t.Fatalf("expected retryUntil to observe attempts %v, got %v", want, seenByCheck)
+	}
+	for i, v := range want {
+		if seenByCheck[i] != v {
+			t.Fatalf("retryUntil saw a stale respObject: expected attempts %v in order, got %v", want, seenByCheck)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package iffy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// JSONNormalizer mutates a decoded JSON object in place, typically to strip
+// volatile fields (timestamps, UUIDs, ...) before it is compared to a
+// snapshot or written to one.
+type JSONNormalizer func(m map[string]interface{})
+
+// EnableSnapshots turns on snapshot testing for the Tester: calls marked with
+// SnapshotResponse/SnapshotHeaders have their response diffed against a
+// golden file stored under dir. Set UPDATE_SNAPSHOTS=1 in the environment to
+// (re)write the golden files instead of comparing against them.
+func (t *Tester) EnableSnapshots(dir string) *Tester {
+	t.snapshotDir = dir
+	return t
+}
+
+// SnapshotResponse marks this call's (normalized) response body to be
+// compared against its golden file.
+func (c *Call) SnapshotResponse() *Call {
+	c.snapshotBody = true
+	return c
+}
+
+// SnapshotHeaders marks the given response headers to be compared against
+// the golden file, alongside the body if SnapshotResponse was also called.
+func (c *Call) SnapshotHeaders(keys ...string) *Call {
+	c.snapshotHeaders = append(c.snapshotHeaders, keys...)
+	return c
+}
+
+// WithJSONNormalizer registers a normalizer applied to the decoded JSON
+// response body before it is snapshotted, so that volatile fields don't
+// cause spurious diffs.
+func (c *Call) WithJSONNormalizer(n JSONNormalizer) *Call {
+	c.jsonNormalizers = append(c.jsonNormalizers, n)
+	return c
+}
+
+// WithRedactedFields replaces the values at the given JSON pointers
+// (RFC 6901, e.g. "/data/0/id") with the literal string "<redacted>" before
+// the response is snapshotted.
+func (c *Call) WithRedactedFields(pointers ...string) *Call {
+	return c.WithJSONNormalizer(func(m map[string]interface{}) {
+		for _, p := range pointers {
+			redactJSONPointer(m, p)
+		}
+	})
+}
+
+// snapshot is the golden-file representation of a call's response.
+type snapshot struct {
+	Body    json.RawMessage   `json:"body,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+func updateSnapshots() bool {
+	return os.Getenv("UPDATE_SNAPSHOTS") == "1"
+}
+
+// runSnapshot normalizes the response and either rewrites or checks it
+// against the call's golden file. It is a no-op if snapshots are disabled
+// for the Tester or unrequested for this call.
+func (t *Tester) runSnapshot(c *Call, r *http.Response, rawBody []byte) {
+	if t.snapshotDir == "" || (!c.snapshotBody && len(c.snapshotHeaders) == 0) {
+		return
+	}
+
+	got := snapshot{Headers: map[string]string{}}
+
+	if c.snapshotBody {
+		var decoded interface{}
+		if err := json.Unmarshal(rawBody, &decoded); err != nil {
+			t.t.Errorf("%s: snapshot: response is not valid JSON: %s", c.Name, err)
+			return
+		}
+		// JSONNormalizer only operates on object-shaped responses (it needs a
+		// field to key off of); array and scalar responses are snapshotted
+		// as-is, unnormalized.
+		if m, ok := decoded.(map[string]interface{}); ok {
+			for _, n := range c.jsonNormalizers {
+				n(m)
+			}
+		}
+		normalized, err := json.MarshalIndent(decoded, "", "  ")
+		if err != nil {
+			t.t.Errorf("%s: snapshot: %s", c.Name, err)
+			return
+		}
+		got.Body = normalized
+	}
+
+	for _, k := range c.snapshotHeaders {
+		got.Headers[k] = r.Header.Get(k)
+	}
+
+	path := filepath.Join(t.snapshotDir, fmt.Sprintf("%s.snap.json", sanitizeSnapshotName(c.Name)))
+
+	if updateSnapshots() {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.t.Errorf("%s: snapshot: %s", c.Name, err)
+			return
+		}
+		out, err := json.MarshalIndent(got, "", "  ")
+		if err != nil {
+			t.t.Errorf("%s: snapshot: %s", c.Name, err)
+			return
+		}
+		if err := ioutil.WriteFile(path, append(out, '\n'), 0o644); err != nil {
+			t.t.Errorf("%s: snapshot: %s", c.Name, err)
+		}
+		return
+	}
+
+	golden, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.t.Errorf("%s: snapshot: no golden file at %s (run with UPDATE_SNAPSHOTS=1 to create it)", c.Name, path)
+		return
+	}
+
+	var want snapshot
+	if err := json.Unmarshal(golden, &want); err != nil {
+		t.t.Errorf("%s: snapshot: invalid golden file %s: %s", c.Name, path, err)
+		return
+	}
+
+	if c.snapshotBody && !jsonEqual(want.Body, got.Body) {
+		t.t.Errorf("%s: snapshot: response body doesn't match %s\nwant:\n%s\ngot:\n%s", c.Name, path, want.Body, got.Body)
+	}
+	for _, k := range c.snapshotHeaders {
+		if want.Headers[k] != got.Headers[k] {
+			t.t.Errorf("%s: snapshot: header %q doesn't match %s: want %q, got %q", c.Name, k, path, want.Headers[k], got.Headers[k])
+		}
+	}
+}
+
+func jsonEqual(a, b json.RawMessage) bool {
+	var av, bv interface{}
+	if json.Unmarshal(a, &av) != nil || json.Unmarshal(b, &bv) != nil {
+		return string(a) == string(b)
+	}
+	na, _ := json.Marshal(av)
+	nb, _ := json.Marshal(bv)
+	return string(na) == string(nb)
+}
+
+func sanitizeSnapshotName(name string) string {
+	replacer := strings.NewReplacer("/", "_", " ", "_")
+	return replacer.Replace(name)
+}
+
+// redactJSONPointer replaces the value at a RFC 6901 JSON pointer with
+// "<redacted>", walking through maps and slices.
+func redactJSONPointer(m map[string]interface{}, pointer string) {
+	rawTokens := strings.Split(strings.TrimPrefix(pointer, "/"), "/")
+	if len(rawTokens) == 0 || rawTokens[0] == "" {
+		return
+	}
+	tokens := make([]string, len(rawTokens))
+	for i, t := range rawTokens {
+		tokens[i] = unescapeJSONPointerToken(t)
+	}
+	var cur interface{} = m
+	for i, tok := range tokens {
+		last := i == len(tokens)-1
+		switch node := cur.(type) {
+		case map[string]interface{}:
+			if last {
+				if _, ok := node[tok]; ok {
+					node[tok] = "<redacted>"
+				}
+				return
+			}
+			cur = node[tok]
+		case []interface{}:
+			idx := indexOf(tok, len(node))
+			if idx < 0 {
+				return
+			}
+			if last {
+				node[idx] = "<redacted>"
+				return
+			}
+			cur = node[idx]
+		default:
+			return
+		}
+	}
+}
+
+// unescapeJSONPointerToken decodes a single RFC 6901 reference token: "~1" is
+// unescaped to "/" before "~0" is unescaped to "~", per the spec's order.
+func unescapeJSONPointerToken(tok string) string {
+	tok = strings.ReplaceAll(tok, "~1", "/")
+	tok = strings.ReplaceAll(tok, "~0", "~")
+	return tok
+}
+
+func indexOf(tok string, length int) int {
+	idx := -1
+	_, err := fmt.Sscanf(tok, "%d", &idx)
+	if err != nil || idx < 0 || idx >= length {
+		return -1
+	}
+	return idx
+}
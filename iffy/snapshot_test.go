@@ -0,0 +1,77 @@
+package iffy
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRunSnapshotArrayResponse(t *testing.T) {
+	dir := t.TempDir()
+	os.Setenv("UPDATE_SNAPSHOTS", "1")
+	defer os.Unsetenv("UPDATE_SNAPSHOTS")
+
+	tester := &Tester{t: t, snapshotDir: dir}
+	c := (&Call{Name: "list"}).SnapshotResponse()
+
+	tester.runSnapshot(c, &http.Response{Header: http.Header{}}, []byte(`[{"id":1},{"id":2}]`))
+
+	if _, err := os.Stat(filepath.Join(dir, "list.snap.json")); err != nil {
+		t.Fatalf("expected a golden file for an array response, got: %s", err)
+	}
+}
+
+func TestRunSnapshotScalarResponse(t *testing.T) {
+	dir := t.TempDir()
+	os.Setenv("UPDATE_SNAPSHOTS", "1")
+	defer os.Unsetenv("UPDATE_SNAPSHOTS")
+
+	tester := &Tester{t: t, snapshotDir: dir}
+	c := (&Call{Name: "count"}).SnapshotResponse()
+
+	tester.runSnapshot(c, &http.Response{Header: http.Header{}}, []byte(`42`))
+
+	if _, err := os.Stat(filepath.Join(dir, "count.snap.json")); err != nil {
+		t.Fatalf("expected a golden file for a scalar response, got: %s", err)
+	}
+}
+
+func TestRedactJSONPointerEscapedSlash(t *testing.T) {
+	m := map[string]interface{}{
+		"data": map[string]interface{}{"a/b": "secret"},
+	}
+	redactJSONPointer(m, "/data/a~1b")
+
+	data := m["data"].(map[string]interface{})
+	if got := data["a/b"]; got != "<redacted>" {
+		t.Fatalf("expected field %q to be redacted via ~1 escaping, got %v", "a/b", got)
+	}
+}
+
+func TestRedactJSONPointerEscapedTilde(t *testing.T) {
+	m := map[string]interface{}{"a~b": "secret"}
+	redactJSONPointer(m, "/a~0b")
+
+	if got := m["a~b"]; got != "<redacted>" {
+		t.Fatalf("expected field %q to be redacted via ~0 escaping, got %v", "a~b", got)
+	}
+}
+
+func TestRedactJSONPointerArrayIndex(t *testing.T) {
+	m := map[string]interface{}{
+		"items": []interface{}{
+			map[string]interface{}{"id": "1"},
+			map[string]interface{}{"id": "2"},
+		},
+	}
+	redactJSONPointer(m, "/items/1/id")
+
+	items := m["items"].([]interface{})
+	if got := items[1].(map[string]interface{})["id"]; got != "<redacted>" {
+		t.Fatalf("expected items[1].id to be redacted, got %v", got)
+	}
+	if got := items[0].(map[string]interface{})["id"]; got != "1" {
+		t.Fatalf("expected items[0].id to be untouched, got %v", got)
+	}
+}
@@ -0,0 +1,209 @@
+package tonic
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Codec decodes a request body into, and encodes a handler's return value
+// to, a single media type. Register one with RegisterCodec to let
+// EnableContentNegotiation dispatch to it based on the request's
+// Content-Type/Accept headers.
+type Codec interface {
+	Decode(r *http.Request, into interface{}) error
+	Encode(w http.ResponseWriter, v interface{}) error
+}
+
+var (
+	codecsMu sync.RWMutex
+	codecs   = map[string]Codec{}
+)
+
+// RegisterCodec installs a Codec for the given media type. "application/json"
+// is registered by default; register your own for XML, YAML, protobuf,
+// msgpack, etc.
+func RegisterCodec(mediaType string, c Codec) {
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+	codecs[mediaType] = c
+}
+
+func getCodec(mediaType string) Codec {
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+	return codecs[mediaType]
+}
+
+// RegisteredContentTypes returns the media types with a Codec registered via
+// RegisterCodec, in no particular order. Doc generators (see
+// tonic/utils/openapi) use it to advertise every content type a route
+// actually accepts instead of assuming JSON.
+func RegisteredContentTypes() []string {
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+	out := make([]string, 0, len(codecs))
+	for mt := range codecs {
+		out = append(out, mt)
+	}
+	return out
+}
+
+func init() {
+	RegisterCodec("application/json", jsonCodec{})
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Decode(r *http.Request, into interface{}) error {
+	return json.NewDecoder(r.Body).Decode(into)
+}
+
+func (jsonCodec) Encode(w http.ResponseWriter, v interface{}) error {
+	return json.NewEncoder(w).Encode(v)
+}
+
+// EnableContentNegotiation installs a BindHook/RenderHook pair that decode
+// the request body with the Codec registered for its Content-Type, and
+// encode the response with the Codec registered for the client's preferred
+// Accept media type, falling back to JSON when either header is absent or
+// unrecognized. Path/query parameters are still bound the way
+// defaultBindHook does; only the body goes through the negotiated codec.
+//
+// multipart/form-data requests bypass codecs entirely: fields tagged
+// `file:"name"` are populated from the matching uploaded part as a
+// *multipart.FileHeader, and fields tagged `form:"name"` are bound from the
+// form value of that name; other fields are left unset.
+func EnableContentNegotiation() {
+	SetBindHook(negotiatedBindHook)
+	SetRenderHook(negotiatedRenderHook)
+}
+
+func negotiatedBindHook(c *gin.Context, i interface{}) error {
+	if err := bindPathAndQuery(c, i); err != nil {
+		return err
+	}
+
+	if c.Request.ContentLength == 0 {
+		return nil
+	}
+
+	mediaType := mediaTypeOf(c.GetHeader("Content-Type"))
+	if strings.HasPrefix(mediaType, "multipart/") {
+		return bindMultipart(c, i)
+	}
+
+	codec := getCodec(mediaType)
+	if codec == nil {
+		codec = getCodec("application/json")
+	}
+	return codec.Decode(c.Request, i)
+}
+
+func negotiatedRenderHook(c *gin.Context, statusCode int, payload interface{}) {
+	if payload == nil {
+		c.Status(statusCode)
+		return
+	}
+
+	mediaType := negotiateAccept(c.GetHeader("Accept"))
+	codec := getCodec(mediaType)
+	if codec == nil {
+		mediaType = "application/json"
+		codec = getCodec(mediaType)
+	}
+
+	c.Header("Content-Type", mediaType)
+	c.Writer.WriteHeader(statusCode)
+	_ = codec.Encode(c.Writer, payload)
+}
+
+// mediaTypeOf strips parameters (charset=..., boundary=...) off a
+// Content-Type/Accept value, e.g. "application/json; charset=utf-8"
+// becomes "application/json".
+func mediaTypeOf(header string) string {
+	mt, _, err := mime.ParseMediaType(header)
+	if err != nil {
+		return strings.TrimSpace(strings.SplitN(header, ";", 2)[0])
+	}
+	return mt
+}
+
+// negotiateAccept picks the first media type in an Accept header (ignoring
+// q= weighting, which is rarely needed for API responses) that has a
+// registered codec, defaulting to JSON if none match or the header is empty.
+func negotiateAccept(accept string) string {
+	for _, candidate := range strings.Split(accept, ",") {
+		mt := mediaTypeOf(candidate)
+		if mt == "" || mt == "*/*" {
+			continue
+		}
+		if getCodec(mt) != nil {
+			return mt
+		}
+	}
+	return "application/json"
+}
+
+// bindMultipart parses a multipart/form-data request, filling `file:"..."`
+// tagged fields with the matching uploaded part and `form:"..."` tagged
+// fields from the form value of that name. Fields with neither tag (and
+// path/query fields) are not touched.
+func bindMultipart(c *gin.Context, i interface{}) error {
+	if err := c.Request.ParseMultipartForm(32 << 20); err != nil {
+		return fmt.Errorf("tonic: parsing multipart form: %w", err)
+	}
+	form := c.Request.MultipartForm
+
+	v := reflect.ValueOf(i).Elem()
+	t := v.Type()
+
+	for idx := 0; idx < t.NumField(); idx++ {
+		field := t.Field(idx)
+		fv := v.Field(idx)
+
+		if _, ok := field.Tag.Lookup("path"); ok {
+			continue
+		}
+		if _, ok := field.Tag.Lookup("query"); ok {
+			continue
+		}
+
+		if tag, ok := field.Tag.Lookup("file"); ok {
+			name := tag
+			if name == "" {
+				name = field.Name
+			}
+			if fv.Type() != reflect.TypeOf((*multipart.FileHeader)(nil)) {
+				return fmt.Errorf("tonic: field %q tagged `file` must be *multipart.FileHeader", field.Name)
+			}
+			if headers := form.File[name]; len(headers) > 0 {
+				fv.Set(reflect.ValueOf(headers[0]))
+			}
+			continue
+		}
+
+		tag, ok := field.Tag.Lookup("form")
+		if !ok {
+			continue
+		}
+		lookup := func(name string) string {
+			if vals := form.Value[name]; len(vals) > 0 {
+				return vals[0]
+			}
+			return ""
+		}
+		if err := bindTagged(lookup, tag, field.Name, fv); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package tonic
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type codecTestIn struct {
+	Name string `json:"name"`
+}
+
+type codecTestOut struct {
+	Greeting string `json:"greeting"`
+}
+
+func TestNegotiatedBindHookDecodesJSON(t *testing.T) {
+	EnableContentNegotiation()
+	defer func() {
+		SetBindHook(defaultBindHook)
+		SetRenderHook(defaultRenderHook)
+	}()
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/greet", Handler(func(c *gin.Context, in *codecTestIn) (*codecTestOut, error) {
+		return &codecTestOut{Greeting: "hi " + in.Name}, nil
+	}, http.StatusOK))
+
+	body, _ := json.Marshal(codecTestIn{Name: "ada"})
+	req, _ := http.NewRequest(http.MethodPost, "/greet", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var out codecTestOut
+	if err := json.Unmarshal(w.Body.Bytes(), &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Greeting != "hi ada" {
+		t.Fatalf("expected greeting %q, got %q", "hi ada", out.Greeting)
+	}
+}
+
+type fileUploadIn struct {
+	Title string                `form:"title"`
+	File  *multipart.FileHeader `file:"upload"`
+}
+
+func TestBindMultipartFileAndFormField(t *testing.T) {
+	EnableContentNegotiation()
+	defer func() {
+		SetBindHook(defaultBindHook)
+		SetRenderHook(defaultRenderHook)
+	}()
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	var got fileUploadIn
+	r.POST("/upload", Handler(func(c *gin.Context, in *fileUploadIn) (*codecTestOut, error) {
+		got = *in
+		return &codecTestOut{Greeting: "ok"}, nil
+	}, http.StatusOK))
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	if err := mw.WriteField("title", "my doc"); err != nil {
+		t.Fatal(err)
+	}
+	fw, err := mw.CreateFormFile("upload", "doc.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fw.Write([]byte("file content")); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, "/upload", &buf)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if got.Title != "my doc" {
+		t.Fatalf("expected form field Title to bind to %q, got %q", "my doc", got.Title)
+	}
+	if got.File == nil || got.File.Filename != "doc.txt" {
+		t.Fatalf("expected the uploaded file to bind, got %+v", got.File)
+	}
+}
+
+func TestMediaTypeOfStripsParameters(t *testing.T) {
+	cases := map[string]string{
+		"application/json; charset=utf-8": "application/json",
+		"application/json":                "application/json",
+		"":                                "",
+	}
+	for in, want := range cases {
+		if got := mediaTypeOf(in); got != want {
+			t.Errorf("mediaTypeOf(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestNegotiateAcceptFallsBackToJSON(t *testing.T) {
+	if got := negotiateAccept("application/xml"); got != "application/json" {
+		t.Fatalf("expected negotiateAccept to fall back to JSON for an unregistered type, got %q", got)
+	}
+	if got := negotiateAccept("application/json"); got != "application/json" {
+		t.Fatalf("expected negotiateAccept to honor a registered type, got %q", got)
+	}
+}
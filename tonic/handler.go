@@ -0,0 +1,303 @@
+package tonic
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"unsafe"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	routesMu  sync.Mutex
+	routes    []*Route
+	routesPtr = map[uintptr]*Route{}
+)
+
+// Route describes a single gin route wired through tonic.Handler, as
+// recorded for the doc generators (see tonic/utils/openapi). It doesn't carry the HTTP method/path itself: doc
+// generators are handed the gin router and correlate gin's own route list
+// (method, path, handler) back to a Route via GetRouteByHandler.
+type Route struct {
+	Default     int
+	InputType   reflect.Type
+	OutputType  reflect.Type
+	HandlerName string
+	// MediaType is set to the content type selected via HandlerWith(Render(...))
+	// for streaming routes, and left empty for regular JSON routes.
+	MediaType string
+}
+
+// GetRoutes returns the routes registered so far through tonic.Handler, in
+// registration order.
+func GetRoutes() []*Route {
+	routesMu.Lock()
+	defer routesMu.Unlock()
+	out := make([]*Route, len(routes))
+	copy(out, routes)
+	return out
+}
+
+// GetRouteByHandler returns the Route registered for a gin.HandlerFunc
+// returned by tonic.Handler, or nil if h wasn't built by tonic.
+func GetRouteByHandler(h gin.HandlerFunc) *Route {
+	routesMu.Lock()
+	defer routesMu.Unlock()
+	return routesPtr[handlerKey(h)]
+}
+
+func registerRoute(h gin.HandlerFunc, r *Route) {
+	routesMu.Lock()
+	defer routesMu.Unlock()
+	routes = append(routes, r)
+	routesPtr[handlerKey(h)] = r
+}
+
+// handlerKey identifies h by its closure value rather than its code pointer:
+// every closure built by Handler (or HandlerWith) shares the same code, so
+// reflect.Value.Pointer alone can't tell two routes apart.
+func handlerKey(h gin.HandlerFunc) uintptr {
+	return uintptr(*(*unsafe.Pointer)(unsafe.Pointer(&h)))
+}
+
+// reflectedHandler holds the reflection info shared by Handler and
+// HandlerWith to call a tonic handler function and bind its input.
+type reflectedHandler struct {
+	fv         reflect.Value
+	hasContext bool
+	inputType  reflect.Type
+	hasOutput  bool
+	outputType reflect.Type
+}
+
+func reflectHandler(f interface{}) *reflectedHandler {
+	fv := reflect.ValueOf(f)
+	ft := fv.Type()
+	if ft.Kind() != reflect.Func {
+		panic("tonic.Handler: handler must be a function")
+	}
+
+	hasContext := ft.NumIn() > 0 && ft.In(0) == reflect.TypeOf(&gin.Context{})
+	inOffset := 0
+	if hasContext {
+		inOffset = 1
+	}
+
+	var inputType reflect.Type
+	if ft.NumIn() > inOffset {
+		inputType = ft.In(inOffset)
+		if inputType.Kind() != reflect.Ptr || inputType.Elem().Kind() != reflect.Struct {
+			panic("tonic.Handler: handler input must be a pointer to a struct")
+		}
+	}
+
+	hasOutput := ft.NumOut() == 2
+	var outputType reflect.Type
+	if hasOutput {
+		outputType = ft.Out(0)
+	}
+
+	return &reflectedHandler{
+		fv:         fv,
+		hasContext: hasContext,
+		inputType:  inputType,
+		hasOutput:  hasOutput,
+		outputType: outputType,
+	}
+}
+
+// bindAndCall binds f's input from c and invokes it, returning the output
+// value (invalid if the handler has none) and the error value.
+func (h *reflectedHandler) bindAndCall(c *gin.Context) (reflect.Value, error) {
+	var args []reflect.Value
+	if h.hasContext {
+		args = append(args, reflect.ValueOf(c))
+	}
+	if h.inputType != nil {
+		in := reflect.New(h.inputType.Elem())
+		if err := bindHook(c, in.Interface()); err != nil {
+			return reflect.Value{}, err
+		}
+		args = append(args, in)
+	}
+
+	results := h.fv.Call(args)
+
+	var outVal reflect.Value
+	var errVal reflect.Value
+	if h.hasOutput {
+		outVal, errVal = results[0], results[1]
+	} else {
+		errVal = results[0]
+	}
+
+	if !errVal.IsNil() {
+		return reflect.Value{}, errVal.Interface().(error)
+	}
+	return outVal, nil
+}
+
+// Handler wraps f into a gin.HandlerFunc.
+//
+// f must be a function of the form:
+//
+//	func(*gin.Context, *InputStruct) (*OutputStruct, error)
+//
+// Either the *gin.Context or the input/output may be omitted, e.g.
+// func(*InputStruct) error, func(*gin.Context) (*OutputStruct, error), ...
+//
+// retcode is the HTTP status written when f returns a nil error.
+func Handler(f interface{}, retcode int) gin.HandlerFunc {
+	rh := reflectHandler(f)
+
+	h := func(c *gin.Context) {
+		outVal, err := rh.bindAndCall(c)
+		if err != nil {
+			status, payload := errHook(c, err)
+			renderHook(c, status, payload)
+			return
+		}
+
+		var payload interface{}
+		if rh.hasOutput && !(outVal.Kind() == reflect.Ptr && outVal.IsNil()) {
+			payload = outVal.Interface()
+		}
+		renderHook(c, retcode, payload)
+	}
+
+	registerRoute(h, &Route{
+		Default:     retcode,
+		InputType:   rh.inputType,
+		OutputType:  rh.outputType,
+		HandlerName: handlerName(f),
+	})
+
+	return h
+}
+
+// handlerName returns the fully-qualified name of the function backing f,
+// used to identify routes in documentation output.
+func handlerName(f interface{}) string {
+	return runtime.FuncForPC(reflect.ValueOf(f).Pointer()).Name()
+}
+
+// defaultBindHook binds path and query parameters described by `path:"..."`
+// and `query:"..."` struct tags, then falls back to JSON for the request
+// body. Tags support a `,required` modifier and a `default=...` value,
+// e.g. `query:"bar,default=foobar"`.
+func defaultBindHook(c *gin.Context, i interface{}) error {
+	if err := bindPathAndQuery(c, i); err != nil {
+		return err
+	}
+
+	if c.Request.ContentLength == 0 {
+		return nil
+	}
+	return c.ShouldBindJSON(i)
+}
+
+// bindPathAndQuery binds i's `path:"..."` and `query:"..."` tagged fields
+// from c, leaving the rest of i untouched. It is shared by defaultBindHook
+// and the negotiated content-type bind hook (see codec.go), which differ
+// only in how they handle the request body.
+func bindPathAndQuery(c *gin.Context, i interface{}) error {
+	v := reflect.ValueOf(i).Elem()
+	t := v.Type()
+
+	for idx := 0; idx < t.NumField(); idx++ {
+		field := t.Field(idx)
+		fv := v.Field(idx)
+
+		if tag, ok := field.Tag.Lookup("path"); ok {
+			if err := bindTagged(c.Param, tag, field.Name, fv); err != nil {
+				return err
+			}
+			continue
+		}
+		if tag, ok := field.Tag.Lookup("query"); ok {
+			lookup := func(name string) string {
+				v, _ := c.GetQuery(name)
+				return v
+			}
+			if err := bindTagged(lookup, tag, field.Name, fv); err != nil {
+				return err
+			}
+			continue
+		}
+	}
+	return nil
+}
+
+// bindTagged resolves a single `name,required,default=value` tag against
+// lookup and assigns the result to fv.
+func bindTagged(lookup func(string) string, tag, fieldName string, fv reflect.Value) error {
+	parts := strings.Split(tag, ",")
+	name := parts[0]
+	if name == "" {
+		name = fieldName
+	}
+
+	var required bool
+	var defaultVal string
+	var hasDefault bool
+	for _, p := range parts[1:] {
+		switch {
+		case p == "required":
+			required = true
+		case strings.HasPrefix(p, "default="):
+			hasDefault = true
+			defaultVal = strings.TrimPrefix(p, "default=")
+		}
+	}
+
+	raw := lookup(name)
+	if raw == "" {
+		if hasDefault {
+			raw = defaultVal
+		} else if required {
+			return fmt.Errorf("missing required parameter %q", name)
+		} else {
+			return nil
+		}
+	}
+
+	return assign(fv, raw)
+}
+
+func assign(fv reflect.Value, raw string) error {
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(raw)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return err
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	default:
+		return fmt.Errorf("tonic: unsupported field kind %s for tagged binding", fv.Kind())
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package tonic
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type handlerTestIn struct {
+	ID   string `path:"id"`
+	Name string `query:"name,required"`
+	N    int    `query:"n,default=7"`
+}
+
+type handlerTestOut struct {
+	Echo string `json:"echo"`
+}
+
+func TestHandlerBindsPathQueryAndDefault(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/items/:id", Handler(func(c *gin.Context, in *handlerTestIn) (*handlerTestOut, error) {
+		return &handlerTestOut{Echo: in.ID + ":" + in.Name + ":" + strconv.Itoa(in.N)}, nil
+	}, http.StatusOK))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/items/42?name=bob", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var out handlerTestOut
+	if err := json.Unmarshal(w.Body.Bytes(), &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Echo != "42:bob:7" {
+		t.Fatalf("expected path/query/default binding to produce %q, got %q", "42:bob:7", out.Echo)
+	}
+}
+
+func TestHandlerMissingRequiredQueryParamIs400(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/items/:id", Handler(func(c *gin.Context, in *handlerTestIn) (*handlerTestOut, error) {
+		return &handlerTestOut{}, nil
+	}, http.StatusOK))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/items/42", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected a missing required query param to be rejected with 400, got %d", w.Code)
+	}
+}
+
+func TestHandlerNoInputNoOutput(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	called := false
+	r.POST("/ping", Handler(func(c *gin.Context) error {
+		called = true
+		return nil
+	}, http.StatusNoContent))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/ping", nil)
+	r.ServeHTTP(w, req)
+
+	if !called {
+		t.Fatal("expected the handler to be invoked")
+	}
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", w.Code)
+	}
+}
+
+func TestHandlerRegistersRoute(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := Handler(func(c *gin.Context, in *handlerTestIn) (*handlerTestOut, error) {
+		return nil, nil
+	}, http.StatusOK)
+
+	route := GetRouteByHandler(h)
+	if route == nil {
+		t.Fatal("expected Handler to register a Route lookup by its gin.HandlerFunc")
+	}
+	if route.Default != http.StatusOK {
+		t.Fatalf("expected Route.Default to be 200, got %d", route.Default)
+	}
+	if route.InputType == nil || route.InputType.Elem().Name() != "handlerTestIn" {
+		t.Fatalf("expected Route.InputType to be *handlerTestIn, got %v", route.InputType)
+	}
+}
@@ -0,0 +1,196 @@
+package tonic
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// ProblemContentType is the media type written by SetProblemErrorHook,
+// as specified by RFC 7807.
+const ProblemContentType = "application/problem+json"
+
+// Problem is an RFC 7807 "problem detail" error, ready to be marshaled as
+// application/problem+json. Build one with NewProblem and the With* methods,
+// or return a type that implements problemConvertible from your handler.
+type Problem struct {
+	Type     string `json:"type,omitempty"`
+	Title    string `json:"title,omitempty"`
+	Status   int    `json:"status,omitempty"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+
+	// Fields carries per-field validation violations, keyed by field name.
+	Fields map[string][]string `json:"fields,omitempty"`
+	// Extensions carries any additional member the caller registered via
+	// WithExtension. Its keys are merged at the top level when marshaled.
+	Extensions map[string]interface{} `json:"-"`
+}
+
+// NewProblem creates a Problem with the given HTTP status and title. Type
+// defaults to "about:blank", as recommended by RFC 7807 when no specific
+// problem type URI is registered.
+func NewProblem(status int, title string) *Problem {
+	return &Problem{
+		Type:   "about:blank",
+		Title:  title,
+		Status: status,
+	}
+}
+
+// Error implements the error interface, so a Problem can be returned
+// directly from a tonic handler.
+func (p *Problem) Error() string {
+	if p.Detail != "" {
+		return fmt.Sprintf("%s: %s", p.Title, p.Detail)
+	}
+	return p.Title
+}
+
+// WithType sets the problem's type URI, identifying the specific error
+// condition (as opposed to Title, which is a human-readable summary).
+func (p *Problem) WithType(t string) *Problem {
+	p.Type = t
+	return p
+}
+
+// WithInstance sets the URI identifying this specific occurrence of the
+// problem (e.g. the request path or a trace ID).
+func (p *Problem) WithInstance(instance string) *Problem {
+	p.Instance = instance
+	return p
+}
+
+// WithDetail sets the human-readable explanation specific to this occurrence.
+func (p *Problem) WithDetail(detail string) *Problem {
+	p.Detail = detail
+	return p
+}
+
+// WithField records a validation violation on the given field. It may be
+// called multiple times for the same field to accumulate several messages.
+func (p *Problem) WithField(field, message string) *Problem {
+	if p.Fields == nil {
+		p.Fields = map[string][]string{}
+	}
+	p.Fields[field] = append(p.Fields[field], message)
+	return p
+}
+
+// WithExtension attaches an additional, problem-specific member that will
+// be merged at the top level of the marshaled JSON object.
+func (p *Problem) WithExtension(key string, value interface{}) *Problem {
+	if p.Extensions == nil {
+		p.Extensions = map[string]interface{}{}
+	}
+	p.Extensions[key] = value
+	return p
+}
+
+// MarshalJSON flattens Extensions into the top-level object alongside the
+// standard RFC 7807 members.
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	type alias Problem // avoid infinite recursion through MarshalJSON
+
+	out := map[string]interface{}{}
+	for k, v := range p.Extensions {
+		out[k] = v
+	}
+
+	std, err := json.Marshal(alias(*p))
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(std, &out); err != nil {
+		return nil, err
+	}
+	return json.Marshal(out)
+}
+
+// problemConvertible lets custom error types describe their own Problem
+// representation, so they render correctly without the caller having to
+// know about every error type in the problem error hook.
+type problemConvertible interface {
+	Problem() *Problem
+}
+
+// SetProblemErrorHook installs an ErrorHook that renders errors returned by
+// tonic handlers as application/problem+json (RFC 7807).
+//
+// It unwraps the error (via errors.As) looking for a *Problem or a type
+// implementing problemConvertible (see tonic/utils/jujerr for a juju/errors
+// adapter); validator.ValidationErrors from the bind stage are rendered as a
+// 400 with one Fields entry per violated field, and anything else falls
+// back to a generic 400 problem.
+func SetProblemErrorHook() {
+	SetErrorHook(ProblemErrorHook)
+}
+
+// ProblemErrorHook is the ErrorHook installed by SetProblemErrorHook. It is
+// exported so that other hooks (e.g. jujerr.ProblemHook) can fall back to it
+// for errors they don't have a more specific mapping for.
+func ProblemErrorHook(c *gin.Context, e error) (int, interface{}) {
+	p := problemFromError(e)
+	c.Header("Content-Type", ProblemContentType)
+	return p.Status, p
+}
+
+func problemFromError(e error) *Problem {
+	var p *Problem
+	if errors.As(e, &p) {
+		return withDefaults(p)
+	}
+
+	var convertible problemConvertible
+	if errors.As(e, &convertible) {
+		return withDefaults(convertible.Problem())
+	}
+
+	var verrs validator.ValidationErrors
+	if errors.As(e, &verrs) {
+		return problemFromValidationErrors(verrs)
+	}
+
+	// Anything unrecognized is most often a bind-stage failure (malformed
+	// JSON, a type mismatch on a path/query parameter, ...) rather than a
+	// handler-internal fault, so it's rendered as a 400, matching
+	// defaultErrorHook's behavior for callers who haven't opted into
+	// problem+json.
+	return NewProblem(http.StatusBadRequest, "Bad Request").WithDetail(e.Error())
+}
+
+// problemFromValidationErrors renders a `binding:"..."` validation failure
+// (as produced by c.ShouldBindJSON) as a 400 Problem with one Fields entry
+// per violated field.
+func problemFromValidationErrors(verrs validator.ValidationErrors) *Problem {
+	p := NewProblem(http.StatusBadRequest, "Bad Request").WithDetail("request validation failed")
+	for _, fe := range verrs {
+		p.WithField(fe.Field(), validationFieldMessage(fe))
+	}
+	return p
+}
+
+func validationFieldMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "len", "min", "max", "gt", "gte", "lt", "lte", "oneof":
+		return fmt.Sprintf("failed validation %q (%s)", fe.Tag(), fe.Param())
+	default:
+		return fmt.Sprintf("failed validation %q", fe.Tag())
+	}
+}
+
+func withDefaults(p *Problem) *Problem {
+	if p.Status == 0 {
+		p.Status = http.StatusInternalServerError
+	}
+	if p.Type == "" {
+		p.Type = "about:blank"
+	}
+	return p
+}
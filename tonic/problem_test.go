@@ -0,0 +1,81 @@
+package tonic
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type testProblemConvertible struct{ msg string }
+
+func (e *testProblemConvertible) Error() string { return e.msg }
+
+func (e *testProblemConvertible) Problem() *Problem {
+	return NewProblem(http.StatusTeapot, "I'm a teapot").WithDetail(e.msg)
+}
+
+func TestProblemFromErrorPassesThroughProblem(t *testing.T) {
+	p := NewProblem(http.StatusConflict, "Conflict").WithDetail("already exists")
+	got := problemFromError(p)
+	if got.Status != http.StatusConflict || got.Title != "Conflict" {
+		t.Fatalf("expected the *Problem to pass through unchanged, got %+v", got)
+	}
+}
+
+func TestProblemFromErrorUsesConvertible(t *testing.T) {
+	got := problemFromError(&testProblemConvertible{msg: "special"})
+	if got.Status != http.StatusTeapot {
+		t.Fatalf("expected problemConvertible to be used, got status %d", got.Status)
+	}
+}
+
+func TestProblemFromErrorValidationErrors(t *testing.T) {
+	type input struct {
+		Name string `validate:"required"`
+		Age  int    `validate:"gte=18"`
+	}
+	err := validator.New().Struct(input{Age: 5})
+	if err == nil {
+		t.Fatal("expected validation to fail")
+	}
+
+	got := problemFromError(err)
+	if got.Status != http.StatusBadRequest {
+		t.Fatalf("expected validation errors to render as 400, got %d", got.Status)
+	}
+	if len(got.Fields["Name"]) == 0 {
+		t.Fatalf("expected a violation for field Name, got %+v", got.Fields)
+	}
+	if len(got.Fields["Age"]) == 0 {
+		t.Fatalf("expected a violation for field Age, got %+v", got.Fields)
+	}
+}
+
+func TestProblemFromErrorGenericFallbackIs400(t *testing.T) {
+	got := problemFromError(errors.New("boom"))
+	if got.Status != http.StatusBadRequest {
+		t.Fatalf("expected an unrecognized error to fall back to 400, got %d", got.Status)
+	}
+}
+
+func TestProblemMarshalJSONFlattensExtensions(t *testing.T) {
+	p := NewProblem(http.StatusBadRequest, "Bad Request").WithExtension("traceId", "abc123")
+
+	b, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		t.Fatal(err)
+	}
+	if m["traceId"] != "abc123" {
+		t.Fatalf("expected the traceId extension to be merged at the top level, got %+v", m)
+	}
+	if m["status"] != float64(http.StatusBadRequest) {
+		t.Fatalf("expected the standard RFC 7807 members to still be present, got %+v", m)
+	}
+}
@@ -0,0 +1,232 @@
+package tonic
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StreamFunc writes a single chunk of a streamed response and flushes it to
+// the client. It is called once per value produced by a streaming handler
+// (a channel, an iterator func, or a []byte chunk of up to 4096 bytes read
+// from an io.Reader).
+type StreamFunc func(c *gin.Context, v interface{}) error
+
+// renderer pairs the content-type written for a media type with the
+// StreamFunc used to flush each chunk of a streaming response.
+type renderer struct {
+	mediaType string
+	stream    StreamFunc
+}
+
+var (
+	renderersMu sync.RWMutex
+	renderers   = map[string]*renderer{}
+)
+
+// RegisterRenderer installs a StreamFunc for the given media type, so that
+// routes built with HandlerWith(..., Render(mediaType)) stream their output
+// using it. Built-in renderers are registered for "text/event-stream"
+// (Server-Sent Events) and "application/x-ndjson" (newline-delimited JSON);
+// register your own for other formats (protobuf, chunked arrays, ...).
+func RegisterRenderer(mediaType string, fn StreamFunc) {
+	renderersMu.Lock()
+	defer renderersMu.Unlock()
+	renderers[mediaType] = &renderer{mediaType: mediaType, stream: fn}
+}
+
+func getRenderer(mediaType string) *renderer {
+	renderersMu.RLock()
+	defer renderersMu.RUnlock()
+	return renderers[mediaType]
+}
+
+func init() {
+	RegisterRenderer("text/event-stream", sseStream)
+	RegisterRenderer("application/x-ndjson", ndjsonStream)
+}
+
+func sseStream(c *gin.Context, v interface{}) error {
+	sw, ok := c.Writer.(io.Writer)
+	if !ok {
+		return fmt.Errorf("tonic: response writer doesn't support streaming")
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(sw, "data: %s\n\n", b); err != nil {
+		return err
+	}
+	c.Writer.Flush()
+	return nil
+}
+
+func ndjsonStream(c *gin.Context, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if _, err := c.Writer.Write(append(b, '\n')); err != nil {
+		return err
+	}
+	c.Writer.Flush()
+	return nil
+}
+
+// handlerConfig holds the options accumulated from HandlerOptions passed to
+// HandlerWith.
+type handlerConfig struct {
+	mediaType string
+}
+
+// HandlerOption customizes a route built with HandlerWith.
+type HandlerOption func(*handlerConfig)
+
+// Render selects the media type a HandlerWith route is rendered as. A
+// StreamFunc must have been registered for it via RegisterRenderer.
+func Render(mediaType string) HandlerOption {
+	return func(cfg *handlerConfig) {
+		cfg.mediaType = mediaType
+	}
+}
+
+// HandlerWith is Handler with support for streaming responses: f may return
+// a channel, a `func() (interface{}, bool)` iterator, or an io.Reader
+// instead of a single value, and its output is written incrementally to the
+// client via the StreamFunc selected with Render, honoring
+// c.Request.Context() so a client disconnect stops the stream.
+//
+// Without Render, HandlerWith behaves exactly like Handler.
+func HandlerWith(f interface{}, retcode int, opts ...HandlerOption) gin.HandlerFunc {
+	cfg := &handlerConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	if cfg.mediaType == "" {
+		return Handler(f, retcode)
+	}
+
+	rdr := getRenderer(cfg.mediaType)
+	if rdr == nil {
+		panic(fmt.Sprintf("tonic: no renderer registered for media type %q", cfg.mediaType))
+	}
+
+	rh := reflectHandler(f)
+	if !rh.hasOutput {
+		panic(fmt.Sprintf("tonic.HandlerWith: handler has no output value, but Render(%q) requires one to stream", cfg.mediaType))
+	}
+
+	h := func(c *gin.Context) {
+		outVal, err := rh.bindAndCall(c)
+		if err != nil {
+			status, payload := errHook(c, err)
+			renderHook(c, status, payload)
+			return
+		}
+
+		next, err := streamSource(c, outVal)
+		if err != nil {
+			status, payload := errHook(c, err)
+			renderHook(c, status, payload)
+			return
+		}
+
+		c.Header("Content-Type", rdr.mediaType)
+		c.Writer.WriteHeader(retcode)
+		streamResponse(c, rdr, next)
+	}
+
+	registerRoute(h, &Route{
+		Default:     retcode,
+		InputType:   rh.inputType,
+		OutputType:  rh.outputType,
+		HandlerName: handlerName(f),
+		MediaType:   cfg.mediaType,
+	})
+
+	return h
+}
+
+// streamResponse drains next until it signals completion or the client
+// disconnects, flushing each produced value through rdr.
+func streamResponse(c *gin.Context, rdr *renderer, next func() (interface{}, bool, error)) {
+	ctx := c.Request.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		v, ok, err := next()
+		if err != nil || !ok {
+			return
+		}
+		if err := rdr.stream(c, v); err != nil {
+			return
+		}
+	}
+}
+
+// streamSource turns a handler's streaming return value (channel, iterator
+// func, or io.Reader) into the next() closure streamResponse expects. out
+// must be valid: HandlerWith rejects no-output handlers at registration
+// time, so this is never reached with a zero Value.
+func streamSource(c *gin.Context, out reflect.Value) (func() (interface{}, bool, error), error) {
+	if !out.IsValid() {
+		return nil, fmt.Errorf("tonic: streaming handler returned no value")
+	}
+	switch out.Kind() {
+	case reflect.Chan:
+		return func() (interface{}, bool, error) {
+			ctx := c.Request.Context()
+			chosen, v, ok := reflect.Select([]reflect.SelectCase{
+				{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())},
+				{Dir: reflect.SelectRecv, Chan: out},
+			})
+			if chosen == 0 || !ok {
+				return nil, false, nil
+			}
+			return v.Interface(), true, nil
+		}, nil
+
+	case reflect.Func:
+		fn, ok := out.Interface().(func() (interface{}, bool))
+		if !ok {
+			return nil, fmt.Errorf("tonic: unsupported iterator signature %s", out.Type())
+		}
+		return func() (interface{}, bool, error) {
+			v, ok := fn()
+			return v, ok, nil
+		}, nil
+
+	case reflect.Interface, reflect.Ptr:
+		if r, ok := out.Interface().(io.Reader); ok {
+			return readerSource(r), nil
+		}
+	}
+	return nil, fmt.Errorf("tonic: unsupported streaming output type %s", out.Type())
+}
+
+// readerSource streams an io.Reader chunk by chunk.
+func readerSource(r io.Reader) func() (interface{}, bool, error) {
+	buf := make([]byte, 4096)
+	return func() (interface{}, bool, error) {
+		n, err := r.Read(buf)
+		if n > 0 {
+			chunk := make([]byte, n)
+			copy(chunk, buf[:n])
+			return chunk, true, nil
+		}
+		if err == io.EOF {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+}
@@ -0,0 +1,75 @@
+package tonic
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestHandlerWithStreamsChannelAsNDJSON(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/stream", HandlerWith(func(c *gin.Context) (chan string, error) {
+		ch := make(chan string, 2)
+		ch <- "a"
+		ch <- "b"
+		close(ch)
+		return ch, nil
+	}, http.StatusOK, Render("application/x-ndjson")))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/stream", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	if len(lines) != 2 || lines[0] != `"a"` || lines[1] != `"b"` {
+		t.Fatalf("expected two ndjson lines for \"a\" and \"b\", got %q", w.Body.String())
+	}
+}
+
+func TestHandlerWithNoOutputPanicsAtRegistration(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected HandlerWith to panic when combining a no-output handler with Render")
+		}
+	}()
+	HandlerWith(func(c *gin.Context) error { return nil }, http.StatusOK, Render("application/x-ndjson"))
+}
+
+func TestStreamSourceReaderChunksAsSSE(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/reader", HandlerWith(func(c *gin.Context) (io.Reader, error) {
+		return strings.NewReader("hello"), nil
+	}, http.StatusOK, Render("text/event-stream")))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/reader", nil)
+	r.ServeHTTP(w, req)
+
+	body := strings.TrimSpace(w.Body.String())
+	if !strings.HasPrefix(body, "data: ") {
+		t.Fatalf("expected an SSE data line, got %q", body)
+	}
+
+	var encoded string
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(body, "data: ")), &encoded); err != nil {
+		t.Fatalf("expected the SSE payload to be a JSON string, got %q: %s", body, err)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		t.Fatalf("expected the payload to be base64-encoded bytes (json.Marshal of []byte): %s", err)
+	}
+	if string(decoded) != "hello" {
+		t.Fatalf("expected the streamed content to be %q, got %q", "hello", decoded)
+	}
+}
@@ -0,0 +1,72 @@
+package tonic
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrorHook is the hook called whenever a tonic handler returns a non-nil
+// error. It inspects the error and returns the HTTP status code and the
+// payload to render to the client.
+type ErrorHook func(c *gin.Context, e error) (int, interface{})
+
+// errHook is the currently installed ErrorHook, defaultErrorHook unless
+// overridden via SetErrorHook.
+var errHook ErrorHook = defaultErrorHook
+
+// SetErrorHook overrides the ErrorHook used to translate handler errors
+// into HTTP responses.
+func SetErrorHook(hook ErrorHook) {
+	if hook == nil {
+		return
+	}
+	errHook = hook
+}
+
+// defaultErrorHook renders any error as a 400 with its message, leaving
+// finer-grained status codes to a custom ErrorHook (see SetProblemErrorHook
+// for a ready-to-use RFC 7807 implementation).
+func defaultErrorHook(c *gin.Context, e error) (int, interface{}) {
+	return http.StatusBadRequest, gin.H{"error": e.Error()}
+}
+
+// BindHook is the hook called to populate a tonic handler's input object
+// from the incoming request (path/query/header/body).
+type BindHook func(c *gin.Context, i interface{}) error
+
+// bindHook is the currently installed BindHook, defaultBindHook unless
+// overridden via SetBindHook.
+var bindHook BindHook = defaultBindHook
+
+// SetBindHook overrides the BindHook used to populate handler input objects.
+func SetBindHook(hook BindHook) {
+	if hook == nil {
+		return
+	}
+	bindHook = hook
+}
+
+// RenderHook is the hook called to write a tonic handler's successful
+// return value to the response.
+type RenderHook func(c *gin.Context, statusCode int, payload interface{})
+
+// renderHook is the currently installed RenderHook, defaultRenderHook unless
+// overridden via SetRenderHook.
+var renderHook RenderHook = defaultRenderHook
+
+// SetRenderHook overrides the RenderHook used to write handler output.
+func SetRenderHook(hook RenderHook) {
+	if hook == nil {
+		return
+	}
+	renderHook = hook
+}
+
+func defaultRenderHook(c *gin.Context, statusCode int, payload interface{}) {
+	if payload == nil {
+		c.Status(statusCode)
+		return
+	}
+	c.JSON(statusCode, payload)
+}
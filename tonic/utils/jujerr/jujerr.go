@@ -0,0 +1,43 @@
+// Package jujerr provides tonic error hooks that translate juju/errors
+// error kinds into HTTP-specific representations.
+package jujerr
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/juju/errors"
+
+	"github.com/loopfz/gadgeto/tonic"
+)
+
+// ProblemHook is a tonic.ErrorHook, to be installed with tonic.SetErrorHook,
+// that renders juju/errors errors as RFC 7807 problem+json documents. It
+// maps the well-known juju error kinds to the matching HTTP status, and
+// falls back to tonic's generic problem conversion (*tonic.Problem,
+// errors.As-unwrappable custom types) for anything else.
+func ProblemHook(c *gin.Context, e error) (int, interface{}) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.IsNotFound(e):
+		status = http.StatusNotFound
+	case errors.IsUnauthorized(e):
+		status = http.StatusUnauthorized
+	case errors.IsForbidden(e):
+		status = http.StatusForbidden
+	case errors.IsAlreadyExists(e):
+		status = http.StatusConflict
+	case errors.IsNotSupported(e):
+		status = http.StatusNotImplemented
+	case errors.IsBadRequest(e), errors.IsNotValid(e):
+		status = http.StatusBadRequest
+	case errors.IsMethodNotAllowed(e):
+		status = http.StatusMethodNotAllowed
+	default:
+		return tonic.ProblemErrorHook(c, e)
+	}
+
+	p := tonic.NewProblem(status, http.StatusText(status)).WithDetail(errors.Cause(e).Error())
+	c.Header("Content-Type", tonic.ProblemContentType)
+	return p.Status, p
+}
@@ -0,0 +1,286 @@
+// Package openapi generates an OpenAPI 3.1 / JSON Schema 2020-12 document
+// from the routes registered through tonic.Handler and tonic.HandlerWith,
+// discovered by matching the gin router's routes to tonic's Route metadata
+// via tonic.GetRouteByHandler. The struct-to-schema walker in schema.go is
+// internal to this package; it is not shared with any other generator.
+//
+// Interface-typed inputs/outputs render as oneOf or anyOf over their
+// registered implementations (RegisterOneOf, RegisterAnyOf); request and
+// response bodies list one content entry per tonic.RegisterCodec'd media
+// type (see tonic.EnableContentNegotiation), not just JSON; and
+// RegisterExample attaches named worked examples to a handler's responses.
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/loopfz/gadgeto/tonic"
+)
+
+// Document is the root of a generated OpenAPI 3.1 document.
+type Document struct {
+	OpenAPI    string                `json:"openapi"`
+	Info       Info                  `json:"info"`
+	Paths      map[string]*PathItem  `json:"paths"`
+	Components Components            `json:"components"`
+	Security   []map[string][]string `json:"security,omitempty"`
+}
+
+// Info is the OpenAPI "info" object.
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version,omitempty"`
+}
+
+// PathItem groups the operations available on a single path.
+type PathItem map[string]*Operation
+
+// Operation describes a single method+path route.
+type Operation struct {
+	Summary     string               `json:"summary,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Parameters  []*Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+// Parameter describes a single path or query parameter.
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Required    bool    `json:"required,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody describes the accepted request payload(s), one schema per
+// negotiated content type.
+type RequestBody struct {
+	Required bool                  `json:"required,omitempty"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+// Response describes a single status code's response payload.
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+// MediaType pairs a schema with optional worked examples for one content type.
+type MediaType struct {
+	Schema   *Schema             `json:"schema,omitempty"`
+	Examples map[string]*Example `json:"examples,omitempty"`
+}
+
+// Example is a named request/response example.
+type Example struct {
+	Summary string      `json:"summary,omitempty"`
+	Value   interface{} `json:"value,omitempty"`
+}
+
+// exampleRegistry holds the examples attached via RegisterExample, keyed by
+// the handler name they decorate (see Route.HandlerName).
+var exampleRegistry = map[string]map[string]*Example{}
+
+// RegisterExample attaches a named example to every response content entry
+// of the route backed by the function named handlerName (its fully
+// qualified name, as tonic records it in Route.HandlerName), surfaced in
+// the generated document under responses.<status>.content.<type>.examples.
+func RegisterExample(handlerName, name string, example *Example) {
+	m := exampleRegistry[handlerName]
+	if m == nil {
+		m = map[string]*Example{}
+		exampleRegistry[handlerName] = m
+	}
+	m[name] = example
+}
+
+// Components holds the shared schema/security definitions referenced by $ref.
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas,omitempty"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme describes one entry of components.securitySchemes.
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	In           string `json:"in,omitempty"`
+	Name         string `json:"name,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+// Option customizes the document produced by OpenAPI.
+type Option func(*Document)
+
+// Version sets the API version advertised in info.version.
+func Version(v string) Option {
+	return func(d *Document) { d.Info.Version = v }
+}
+
+// Security declares a global security scheme, e.g.
+// Security("bearerAuth", &SecurityScheme{Type: "http", Scheme: "bearer"}).
+func Security(name string, scheme *SecurityScheme) Option {
+	return func(d *Document) {
+		d.Components.SecuritySchemes[name] = scheme
+		d.Security = append(d.Security, map[string][]string{name: {}})
+	}
+}
+
+// OpenAPI walks the routes registered on r via tonic.Handler/HandlerWith and
+// returns a gin.HandlerFunc serving the resulting OpenAPI 3.1 document as
+// JSON, e.g.:
+//
+//	r.GET("/openapi.json", openapi.OpenAPI(r, "MyAPI"))
+func OpenAPI(r *gin.Engine, title string, opts ...Option) gin.HandlerFunc {
+	doc := &Document{
+		OpenAPI: "3.1.0",
+		Info:    Info{Title: title},
+		Paths:   map[string]*PathItem{},
+		Components: Components{
+			Schemas:         map[string]*Schema{},
+			SecuritySchemes: map[string]*SecurityScheme{},
+		},
+	}
+	for _, opt := range opts {
+		opt(doc)
+	}
+
+	comps := newComponents()
+
+	for _, ri := range r.Routes() {
+		route := tonic.GetRouteByHandler(ri.HandlerFunc)
+		if route == nil {
+			continue // not a tonic-wired route, nothing to document
+		}
+
+		op := &Operation{
+			Summary:   route.HandlerName,
+			Responses: map[string]*Response{},
+		}
+
+		if route.InputType != nil {
+			op.Parameters = pathAndQueryParams(comps, route.InputType)
+			if body := comps.requestBodySchema(route.InputType); body != nil {
+				op.RequestBody = &RequestBody{
+					Required: true,
+					Content:  contentForSchema(body),
+				}
+			}
+		}
+
+		status := route.Default
+		if status == 0 {
+			status = http.StatusOK
+		}
+		resp := &Response{Description: http.StatusText(status)}
+		if route.OutputType != nil {
+			if route.MediaType != "" {
+				// A streaming route (HandlerWith + Render) only ever writes
+				// the one media type it was registered with.
+				resp.Content = map[string]*MediaType{route.MediaType: {Schema: comps.schemaFor(route.OutputType)}}
+			} else {
+				resp.Content = contentForSchema(comps.schemaFor(route.OutputType))
+			}
+			if examples := exampleRegistry[route.HandlerName]; len(examples) > 0 {
+				for _, mt := range resp.Content {
+					mt.Examples = examples
+				}
+			}
+		}
+		op.Responses[strconv.Itoa(status)] = resp
+
+		pathItem, ok := doc.Paths[ri.Path]
+		if !ok {
+			pathItem = &PathItem{}
+			doc.Paths[ri.Path] = pathItem
+		}
+		(*pathItem)[strings.ToLower(ri.Method)] = op
+	}
+
+	doc.Components.Schemas = comps.schemas
+
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, doc)
+	}
+}
+
+// contentForSchema builds one content entry per media type with a Codec
+// registered via tonic.RegisterCodec (see tonic/codec.go), so a route using
+// EnableContentNegotiation advertises every encoding it actually accepts or
+// produces instead of assuming JSON.
+func contentForSchema(schema *Schema) map[string]*MediaType {
+	mediaTypes := tonic.RegisteredContentTypes()
+	sort.Strings(mediaTypes)
+	content := make(map[string]*MediaType, len(mediaTypes))
+	for _, mt := range mediaTypes {
+		content[mt] = &MediaType{Schema: schema}
+	}
+	return content
+}
+
+// pathAndQueryParams builds the parameter list for an input struct's
+// `path:"..."` and `query:"..."` tagged fields.
+func pathAndQueryParams(comps *components, t reflect.Type) []*Parameter {
+	t = derefType(t)
+	var params []*Parameter
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if tag, ok := f.Tag.Lookup("path"); ok {
+			params = append(params, paramFromTag(comps, "path", tag, f))
+		} else if tag, ok := f.Tag.Lookup("query"); ok {
+			params = append(params, paramFromTag(comps, "query", tag, f))
+		}
+	}
+	return params
+}
+
+func paramFromTag(comps *components, in, tag string, f reflect.StructField) *Parameter {
+	parts := strings.Split(tag, ",")
+	name := parts[0]
+	if name == "" {
+		name = f.Name
+	}
+	required := in == "path"
+	for _, p := range parts[1:] {
+		if p == "required" {
+			required = true
+		}
+	}
+	return &Parameter{
+		Name:        name,
+		In:          in,
+		Required:    required,
+		Description: f.Tag.Get("description"),
+		Schema:      comps.schemaFor(f.Type),
+	}
+}
+
+// requestBodySchema builds the schema for an input struct's body, skipping
+// it entirely if the struct has no JSON-bound fields (i.e. it's made up
+// purely of path/query parameters).
+func (c *components) requestBodySchema(t reflect.Type) *Schema {
+	dt := derefType(t)
+	if dt.Kind() != reflect.Struct {
+		return nil
+	}
+	for i := 0; i < dt.NumField(); i++ {
+		f := dt.Field(i)
+		if _, ok := f.Tag.Lookup("path"); ok {
+			continue
+		}
+		if _, ok := f.Tag.Lookup("query"); ok {
+			continue
+		}
+		if name, omit := jsonFieldName(f); !omit && name != "" {
+			return c.schemaFor(t)
+		}
+	}
+	return nil
+}
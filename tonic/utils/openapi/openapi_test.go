@@ -0,0 +1,112 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/loopfz/gadgeto/tonic"
+)
+
+type widgetIn struct {
+	ID   string `path:"id"`
+	Name string `query:"name,required"`
+}
+
+type widgetOut struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestOpenAPIDocumentsRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/widgets/:id", tonic.Handler(func(c *gin.Context, in *widgetIn) (*widgetOut, error) {
+		return &widgetOut{ID: in.ID, Name: in.Name}, nil
+	}, http.StatusOK))
+	r.GET("/openapi.json", OpenAPI(r, "Widgets", Version("1.0.0")))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/openapi.json", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+
+	var doc Document
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Info.Version != "1.0.0" {
+		t.Fatalf("expected the Version option to set info.version, got %q", doc.Info.Version)
+	}
+
+	item, ok := doc.Paths["/widgets/:id"]
+	if !ok {
+		t.Fatalf("expected a path entry for /widgets/:id, got %+v", doc.Paths)
+	}
+	op, ok := (*item)["get"]
+	if !ok {
+		t.Fatalf("expected a GET operation, got %+v", item)
+	}
+	if len(op.Parameters) != 2 {
+		t.Fatalf("expected 2 parameters (path %q and query %q), got %+v", "id", "name", op.Parameters)
+	}
+	var sawID, sawName bool
+	for _, p := range op.Parameters {
+		switch p.Name {
+		case "id":
+			sawID = p.In == "path"
+		case "name":
+			sawName = p.In == "query"
+		}
+	}
+	if !sawID || !sawName {
+		t.Fatalf("expected an %q path parameter and a %q query parameter, got %+v", "id", "name", op.Parameters)
+	}
+	resp, ok := op.Responses["200"]
+	if !ok {
+		t.Fatalf("expected a 200 response, got %+v", op.Responses)
+	}
+	if resp.Content["application/json"] == nil {
+		t.Fatalf("expected a JSON content entry, got %+v", resp.Content)
+	}
+	if _, ok := doc.Components.Schemas["widgetOut"]; !ok {
+		t.Fatal("expected widgetOut to be registered as a component schema")
+	}
+}
+
+func TestRegisterExampleSurfacesInResponse(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	h := tonic.Handler(func(c *gin.Context) (*widgetOut, error) {
+		return &widgetOut{ID: "1", Name: "demo"}, nil
+	}, http.StatusOK)
+	r.GET("/widgets/demo", h)
+
+	// OpenAPI builds the document once, at registration time, so the example
+	// must be registered before OpenAPI(r, ...) is called, not just before
+	// the request that reads /openapi.json.
+	route := tonic.GetRouteByHandler(h)
+	RegisterExample(route.HandlerName, "demo", &Example{Summary: "a demo widget", Value: widgetOut{ID: "1", Name: "demo"}})
+
+	r.GET("/openapi.json", OpenAPI(r, "Widgets"))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/openapi.json", nil)
+	r.ServeHTTP(w, req)
+
+	var doc Document
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	op := (*doc.Paths["/widgets/demo"])["get"]
+	ex := op.Responses["200"].Content["application/json"].Examples["demo"]
+	if ex == nil || ex.Summary != "a demo widget" {
+		t.Fatalf("expected the registered example to surface in the response content, got %+v", op.Responses["200"].Content)
+	}
+}
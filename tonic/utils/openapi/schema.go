@@ -0,0 +1,194 @@
+package openapi
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Schema is a (deliberately partial) JSON Schema 2020-12 node, enough to
+// describe the structs tonic handlers bind and return.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"`
+}
+
+// oneOfRegistry maps an interface type to the concrete types that can be
+// returned in its place, populated via RegisterOneOf.
+var oneOfRegistry = map[reflect.Type][]reflect.Type{}
+
+// anyOfRegistry is oneOfRegistry's counterpart for RegisterAnyOf.
+var anyOfRegistry = map[reflect.Type][]reflect.Type{}
+
+// RegisterOneOf declares that handlers returning the iface interface may
+// actually produce any of impls, so the generated schema for iface is an
+// OpenAPI oneOf over each implementation's own schema.
+func RegisterOneOf(iface interface{}, impls ...interface{}) {
+	oneOfRegistry[reflect.TypeOf(iface).Elem()] = implTypesOf(impls)
+}
+
+// RegisterAnyOf is like RegisterOneOf, but advertises the relationship as
+// OpenAPI anyOf instead of oneOf: use it when a returned value may validly
+// match more than one of impls' schemas at once (e.g. impls overlap), which
+// oneOf would reject.
+func RegisterAnyOf(iface interface{}, impls ...interface{}) {
+	anyOfRegistry[reflect.TypeOf(iface).Elem()] = implTypesOf(impls)
+}
+
+func implTypesOf(impls []interface{}) []reflect.Type {
+	implTypes := make([]reflect.Type, len(impls))
+	for i, impl := range impls {
+		implTypes[i] = derefType(reflect.TypeOf(impl))
+	}
+	return implTypes
+}
+
+// components accumulates the schemas referenced by $ref while walking
+// routes, keyed by schema name (the Go type name).
+type components struct {
+	schemas map[string]*Schema
+}
+
+func newComponents() *components {
+	return &components{schemas: map[string]*Schema{}}
+}
+
+// schemaFor returns the Schema for t. Struct types get a $ref Schema pointing
+// at their definition, registered (with anything they reference) in
+// c.schemas on first use; primitives, slices, arrays, maps and interfaces
+// get an inline schema.
+func (c *components) schemaFor(t reflect.Type) *Schema {
+	t = derefType(t)
+
+	if t.Kind() == reflect.Interface {
+		if impls, ok := oneOfRegistry[t]; ok {
+			s := &Schema{}
+			for _, impl := range impls {
+				s.OneOf = append(s.OneOf, c.schemaFor(impl))
+			}
+			return s
+		}
+		if impls, ok := anyOfRegistry[t]; ok {
+			s := &Schema{}
+			for _, impl := range impls {
+				s.AnyOf = append(s.AnyOf, c.schemaFor(impl))
+			}
+			return s
+		}
+		return &Schema{}
+	}
+
+	if s := primitiveSchema(t); s != nil {
+		return s
+	}
+
+	if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		return &Schema{Type: "array", Items: c.schemaFor(t.Elem())}
+	}
+
+	if t.Kind() == reflect.Map {
+		return &Schema{Type: "object", AdditionalProperties: c.schemaFor(t.Elem())}
+	}
+
+	if t.Kind() != reflect.Struct {
+		return &Schema{}
+	}
+
+	name := t.Name()
+	if name == "" {
+		name = t.String()
+	}
+	if _, ok := c.schemas[name]; !ok {
+		// Insert a placeholder first so that self-referencing / cyclic
+		// struct schemas don't recurse forever.
+		c.schemas[name] = &Schema{}
+		c.schemas[name] = c.structSchema(t)
+	}
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+func (c *components) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" { // unexported
+			continue
+		}
+
+		// Path/query-bound fields are described in the operation's
+		// parameters, not in the request/response body schema.
+		if _, ok := f.Tag.Lookup("path"); ok {
+			continue
+		}
+		if _, ok := f.Tag.Lookup("query"); ok {
+			continue
+		}
+
+		name, omit := jsonFieldName(f)
+		if omit {
+			continue
+		}
+
+		prop := c.schemaFor(f.Type)
+		if desc := f.Tag.Get("description"); desc != "" {
+			prop = &Schema{Ref: prop.Ref, Type: prop.Type, Format: prop.Format,
+				Properties: prop.Properties, Items: prop.Items, OneOf: prop.OneOf, AnyOf: prop.AnyOf,
+				AdditionalProperties: prop.AdditionalProperties, Description: desc}
+		}
+		s.Properties[name] = prop
+
+		if strings.Contains(f.Tag.Get("binding"), "required") {
+			s.Required = append(s.Required, name)
+		}
+	}
+
+	return s
+}
+
+func primitiveSchema(t reflect.Type) *Schema {
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	default:
+		return nil
+	}
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// jsonFieldName returns the property name for f per its `json` tag,
+// reporting omit=true for fields tagged `json:"-"` or left unexported.
+func jsonFieldName(f reflect.StructField) (name string, omit bool) {
+	if f.PkgPath != "" { // unexported
+		return "", true
+	}
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", true
+	}
+	parts := strings.Split(tag, ",")
+	if parts[0] != "" {
+		return parts[0], false
+	}
+	return f.Name, false
+}
@@ -0,0 +1,87 @@
+package openapi
+
+import (
+	"reflect"
+	"testing"
+)
+
+type selfRefNode struct {
+	Value    string         `json:"value"`
+	Children []*selfRefNode `json:"children"`
+}
+
+func TestSchemaForSelfReferencingStruct(t *testing.T) {
+	comps := newComponents()
+	s := comps.schemaFor(reflect.TypeOf(selfRefNode{}))
+
+	if s.Ref == "" {
+		t.Fatalf("expected a $ref for the struct, got %+v", s)
+	}
+	def, ok := comps.schemas["selfRefNode"]
+	if !ok {
+		t.Fatal("expected selfRefNode to be registered in components.schemas")
+	}
+	children, ok := def.Properties["children"]
+	if !ok {
+		t.Fatal("expected a children property")
+	}
+	if children.Type != "array" || children.Items == nil || children.Items.Ref == "" {
+		t.Fatalf("expected children to be an array of $ref selfRefNode, got %+v", children)
+	}
+}
+
+type schemaShapeSquare struct {
+	Side int `json:"side"`
+}
+
+type schemaShapeCircle struct {
+	Radius int `json:"radius"`
+}
+
+type schemaShape interface{ isSchemaShape() }
+
+func (schemaShapeSquare) isSchemaShape() {}
+func (schemaShapeCircle) isSchemaShape() {}
+
+func TestSchemaForOneOf(t *testing.T) {
+	RegisterOneOf((*schemaShape)(nil), schemaShapeSquare{}, schemaShapeCircle{})
+
+	comps := newComponents()
+	s := comps.schemaFor(reflect.TypeOf((*schemaShape)(nil)).Elem())
+	if len(s.OneOf) != 2 {
+		t.Fatalf("expected 2 oneOf entries, got %d", len(s.OneOf))
+	}
+	if len(s.AnyOf) != 0 {
+		t.Fatalf("expected no anyOf entries for a RegisterOneOf interface, got %d", len(s.AnyOf))
+	}
+}
+
+type schemaTagSet struct {
+	Name string `json:"name"`
+}
+
+type schemaAnyTag interface{ isSchemaAnyTag() }
+
+func (schemaTagSet) isSchemaAnyTag() {}
+
+func TestSchemaForAnyOf(t *testing.T) {
+	RegisterAnyOf((*schemaAnyTag)(nil), schemaTagSet{})
+
+	comps := newComponents()
+	s := comps.schemaFor(reflect.TypeOf((*schemaAnyTag)(nil)).Elem())
+	if len(s.AnyOf) != 1 {
+		t.Fatalf("expected 1 anyOf entry, got %d", len(s.AnyOf))
+	}
+	if len(s.OneOf) != 0 {
+		t.Fatalf("expected no oneOf entries for a RegisterAnyOf interface, got %d", len(s.OneOf))
+	}
+}
+
+func TestSchemaForUnregisteredInterfaceIsEmpty(t *testing.T) {
+	type unregistered interface{ unused() }
+	comps := newComponents()
+	s := comps.schemaFor(reflect.TypeOf((*unregistered)(nil)).Elem())
+	if s.Ref != "" || s.Type != "" || len(s.OneOf) != 0 || len(s.AnyOf) != 0 {
+		t.Fatalf("expected an empty schema for an interface with no RegisterOneOf/RegisterAnyOf, got %+v", s)
+	}
+}